@@ -6,16 +6,27 @@
 // uses github.com/go-sql-driver/mysql which requires
 // MySQL (4.1+), MariaDB, Percona Server, Google CloudSQL or Sphinx (2.2.3+)
 //
+// NewMySQL is the MySQL-only, environment-variable-configured entry point.
+// For Postgres or SQLite, or for JSON/YAML-file configuration, use
+// storage.Config and storage/mysql.Config instead; NewMySQL is now one
+// config source among several rather than the only way to connect.
+//
 
 package mysql
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"time"
 
+	"github.com/skeptycal/mysql/migrate"
+
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -30,6 +41,10 @@ const (
 
 	// this is the 'driver name' used by helper functions that smooth out connections
 	mySqlDriverName = "mysql"
+
+	defaultConnMaxLifetime = time.Minute * 3
+	defaultMaxOpenConns    = 10
+	defaultMaxIdleConns    = 10
 )
 
 // NewDBConfig returns a new MySQL database connection configuration object.
@@ -45,17 +60,111 @@ func NewMySQL() (MySQL, error) {
 	d := new(mySQL)
 	d.username = username
 	d.password = password
+	d.host = defaultMySQLHost
+	d.port = defaultMySQLPort
+	d.connMaxLifetime = defaultConnMaxLifetime
+	d.maxOpenConns = defaultMaxOpenConns
+	d.maxIdleConns = defaultMaxIdleConns
 
 	return d, nil
 }
 
+// NewMySQLWithDriver returns a MySQL configured to connect through driver
+// instead of relying on the blank-import global registry that
+// github.com/go-sql-driver/mysql populates via sql.Register. This lets
+// callers inject their own driver.Connector, for example a test double or a
+// connector built with a custom net.Dialer, without ever calling sql.Open.
+//
+// Pool tuning and connection defaults match NewMySQL and can be overridden
+// with Option values.
+func NewMySQLWithDriver(connector driver.Connector, opts ...Option) (MySQL, error) {
+	if connector == nil {
+		return nil, errors.New("mysql: connector must not be nil")
+	}
+
+	d := &mySQL{
+		host:            defaultMySQLHost,
+		port:            defaultMySQLPort,
+		connector:       connector,
+		connMaxLifetime: defaultConnMaxLifetime,
+		maxOpenConns:    defaultMaxOpenConns,
+		maxIdleConns:    defaultMaxIdleConns,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d, nil
+}
+
+// Option configures a mySQL connection created by NewMySQLWithDriver. Options
+// are applied in the order they are given, so a later option overrides an
+// earlier one.
+type Option func(*mySQL)
+
+// WithHost overrides the host used to build the connection DSN.
+func WithHost(host string) Option {
+	return func(db *mySQL) { db.host = host }
+}
+
+// WithPort overrides the port used to build the connection DSN.
+func WithPort(port string) Option {
+	return func(db *mySQL) { db.port = port }
+}
+
+// WithTLS sets the name of a TLS config registered with the driver, e.g. via
+// mysql.RegisterTLSConfig, to use for the connection.
+func WithTLS(name string) Option {
+	return func(db *mySQL) { db.tls = name }
+}
+
+// WithConnMaxLifetime overrides the maximum amount of time a connection may
+// be reused, as set by (*sql.DB).SetConnMaxLifetime.
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(db *mySQL) { db.connMaxLifetime = d }
+}
+
+// WithMaxOpenConns overrides the maximum number of open connections to the
+// database, as set by (*sql.DB).SetMaxOpenConns.
+func WithMaxOpenConns(n int) Option {
+	return func(db *mySQL) { db.maxOpenConns = n }
+}
+
+// WithMaxIdleConns overrides the maximum number of idle connections kept in
+// the pool, as set by (*sql.DB).SetMaxIdleConns.
+func WithMaxIdleConns(n int) Option {
+	return func(db *mySQL) { db.maxIdleConns = n }
+}
+
+// WithLogger sets a logger used to report connection events. A nil logger
+// disables logging.
+func WithLogger(logger *log.Logger) Option {
+	return func(db *mySQL) { db.logger = logger }
+}
+
+// WithParams sets extra DSN parameters, such as "charset" or "tls", that are
+// appended to the connection string. Calling WithParams more than once
+// merges into the existing set rather than replacing it.
+func WithParams(params map[string]string) Option {
+	return func(db *mySQL) {
+		if db.params == nil {
+			db.params = make(map[string]string, len(params))
+		}
+		for k, v := range params {
+			db.params[k] = v
+		}
+	}
+}
+
 // MySQL defines the interface to the MySQL database connection
 type MySQL interface {
 	Auth() string
 	DSN(database string) string
 	Open(dbname string) (*sql.DB, error)
+	OpenDB() (*sql.DB, error)
 	Load(file string) error
 	Save(file string) error
+	Migrator(dbname string, fsys embed.FS, dir string) (*migrate.Migrator, error)
 }
 
 type mySQL struct {
@@ -64,6 +173,18 @@ type mySQL struct {
 	host     string `default:"localhost"` // defaults for localhost are most secure
 	port     string `default:"33060"`     // depending on the MySQL version; this may need to be 3306
 	logging  bool   `default:"false"`
+
+	tls    string
+	params map[string]string
+	logger *log.Logger
+
+	// connector, when set (via NewMySQLWithDriver), is used by OpenDB in
+	// place of the driver name/DSN pair that Open passes to sql.Open.
+	connector driver.Connector
+
+	connMaxLifetime time.Duration
+	maxOpenConns    int
+	maxIdleConns    int
 }
 
 // Open opens a database specified by its database driver name and a driver-specific data source name, usually consisting of at least a database name and connection information.
@@ -78,37 +199,124 @@ func (db mySQL) Open(dbname string) (*sql.DB, error) {
 	dbconnection, err := sql.Open(mySqlDriverName, db.DSN(dbname))
 
 	if err != nil {
+		db.logf("mysql: open %s:%s/%s: %v", db.host, db.port, dbname, err)
 		return nil, err
 	}
 
-	// See "Important settings" section.
-	dbconnection.SetConnMaxLifetime(time.Minute * 3)
-	dbconnection.SetMaxOpenConns(10)
-	dbconnection.SetMaxIdleConns(10)
+	db.tunePool(dbconnection)
+	db.logf("mysql: opened %s:%s/%s", db.host, db.port, dbname)
 	return dbconnection, nil
 }
 
+// OpenDB opens a database using the driver.Connector supplied to
+// NewMySQLWithDriver, bypassing sql.Open and the driver-name/DSN pair
+// entirely. It returns an error if db was not built with
+// NewMySQLWithDriver.
+func (db mySQL) OpenDB() (*sql.DB, error) {
+	if db.connector == nil {
+		err := errors.New("mysql: OpenDB requires a MySQL built with NewMySQLWithDriver")
+		db.logf("mysql: %v", err)
+		return nil, err
+	}
+
+	dbconnection := sql.OpenDB(db.connector)
+	db.tunePool(dbconnection)
+	db.logf("mysql: opened connection via injected driver.Connector")
+	return dbconnection, nil
+}
+
+// tunePool applies the configured connection pool settings to conn, logging
+// each one if db was built with WithLogger. See "Important settings"
+// section.
+func (db mySQL) tunePool(conn *sql.DB) {
+	conn.SetConnMaxLifetime(db.connMaxLifetime)
+	conn.SetMaxOpenConns(db.maxOpenConns)
+	conn.SetMaxIdleConns(db.maxIdleConns)
+	db.logf("mysql: pool tuned: connMaxLifetime=%s maxOpenConns=%d maxIdleConns=%d", db.connMaxLifetime, db.maxOpenConns, db.maxIdleConns)
+}
+
+// logf reports a connection event to db.logger, if one was set with
+// WithLogger. It is a no-op otherwise.
+func (db mySQL) logf(format string, args ...interface{}) {
+	if db.logger == nil {
+		return
+	}
+	db.logger.Printf(format, args...)
+}
+
+// Auth returns the "user:password" credential pair used to authenticate
+// with the server. It is the piece of DSN that identifies the connecting
+// user, split out on its own since callers sometimes need to log or
+// compare credentials without building a full DSN.
+func (db mySQL) Auth() string {
+	return fmt.Sprintf("%s:%s", db.username, db.password)
+}
+
 // DSN returns the entire DSN authentication string including a database name.
 // Using "" for the database name will return a generic connection to the server
 // that allows listing and choosing different database names.
+//
+// DSN delegates to DSNConfig.FormatDSN so the result is always a valid DSN
+// accepted by github.com/go-sql-driver/mysql, including any TLS config
+// name or extra params set via WithTLS/WithParams.
 func (db mySQL) DSN(database string) string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s/%s)", db.username, db.password, db.host, db.port, database)
+	return DSNConfig{
+		User:      db.username,
+		Password:  db.password,
+		Host:      db.host,
+		Port:      db.port,
+		Database:  database,
+		ParseTime: true,
+		TLSConfig: db.tls,
+		Params:    db.params,
+	}.FormatDSN()
 }
 
-// Load loads the database configuration from a json file
-//
-// Not Implemented
-func (db mySQL) Load(file string) error {
-	// load json config file
-	return NotImplemented
+// configFile is the on-disk JSON representation of a mySQL config, as read
+// and written by Load and Save. It is the file-backed sibling of the
+// MYSQL_USERNAME/MYSQL_PASSWORD environment variables that NewMySQL reads;
+// see the storage package for JSON/YAML loading of the broader
+// multi-backend storage.Config.
+type configFile struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
 }
 
-// Load saves the database configuration to a json file
-//
-// Not Implemented
-func (db mySQL) Save(file string) error {
-	// save json config file
-	return NotImplemented
+// Load loads the database configuration from a json file written by Save.
+func (db *mySQL) Load(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var cfg configFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+
+	db.username = cfg.Username
+	db.password = cfg.Password
+	db.host = cfg.Host
+	db.port = cfg.Port
+	return nil
+}
+
+// Save saves the database configuration to a json file readable by Load.
+func (db *mySQL) Save(file string) error {
+	cfg := configFile{
+		Username: db.username,
+		Password: db.password,
+		Host:     db.host,
+		Port:     db.port,
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o600)
 }
 
 // NotImplemented returns an error if the method is not yet implemented
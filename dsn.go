@@ -0,0 +1,166 @@
+package mysql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// defaultCharset is used by DSNConfig.FormatDSN when neither Charset nor
+// Params["charset"] is set.
+const defaultCharset = "utf8mb4"
+
+// DSNConfig builds a MySQL DSN string. It replaces the hand-assembled
+// fmt.Sprintf("%s:%s@tcp(%s:%s/%s)", ...) that mySQL.DSN used to produce
+// (which is missing the closing paren before the database name and has no
+// way to express charset, parseTime, TLS, or extra params) by delegating
+// the actual formatting to github.com/go-sql-driver/mysql's own Config, so
+// the result is guaranteed to be syntactically correct and to round-trip
+// through ParseDSN.
+type DSNConfig struct {
+	User     string
+	Password string
+	Host     string
+	Port     string
+	Database string
+
+	// Charset takes precedence over a "charset" entry in Params; when both
+	// are empty, "utf8mb4" is used.
+	Charset   string
+	Collation string
+	ParseTime bool
+	Loc       *time.Location
+
+	Timeout      time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// TLSConfig is either "true", "skip-verify", or the name of a config
+	// registered with RegisterTLSConfig.
+	TLSConfig string
+
+	// DisableNativePasswords, when true, disables the mysql_native_password
+	// auth plugin. Left false (the zero value), go-sql-driver/mysql's own
+	// default of allowing it is used, since that is the auth plugin most
+	// MySQL servers are still configured with.
+	DisableNativePasswords bool
+	MultiStatements        bool
+	InterpolateParams      bool
+
+	// Params holds arbitrary extra DSN parameters, merged with Charset.
+	Params map[string]string
+}
+
+// FormatDSN renders c as a DSN string.
+func (c DSNConfig) FormatDSN() string {
+	cfg := mysqldriver.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(c.Host, c.Port)
+	cfg.DBName = c.Database
+	cfg.Collation = c.Collation
+	cfg.ParseTime = c.ParseTime
+	cfg.Loc = c.Loc
+	cfg.Timeout = c.Timeout
+	cfg.ReadTimeout = c.ReadTimeout
+	cfg.WriteTimeout = c.WriteTimeout
+	cfg.TLSConfig = c.TLSConfig
+	cfg.AllowNativePasswords = !c.DisableNativePasswords
+	cfg.MultiStatements = c.MultiStatements
+	cfg.InterpolateParams = c.InterpolateParams
+
+	charset := c.Charset
+	if charset == "" {
+		charset = c.Params["charset"]
+	}
+	if charset == "" {
+		charset = defaultCharset
+	}
+	params := make(map[string]string, len(c.Params)+1)
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	params["charset"] = charset
+	cfg.Params = params
+
+	return cfg.FormatDSN()
+}
+
+// ParseDSN parses a DSN string produced by FormatDSN, or any TCP DSN accepted
+// by github.com/go-sql-driver/mysql, back into a DSNConfig. A DSN whose
+// address has no host:port form, such as a unix socket path, returns an
+// error.
+func ParseDSN(dsn string) (DSNConfig, error) {
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return DSNConfig{}, err
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return DSNConfig{}, fmt.Errorf("mysql: parsing address %q: %w", cfg.Addr, err)
+	}
+
+	charset := ""
+	params := make(map[string]string, len(cfg.Params))
+	for k, v := range cfg.Params {
+		if k == "charset" {
+			charset = v
+			continue
+		}
+		params[k] = v
+	}
+
+	return DSNConfig{
+		User:                   cfg.User,
+		Password:               cfg.Passwd,
+		Host:                   host,
+		Port:                   port,
+		Database:               cfg.DBName,
+		Charset:                charset,
+		Collation:              cfg.Collation,
+		ParseTime:              cfg.ParseTime,
+		Loc:                    cfg.Loc,
+		Timeout:                cfg.Timeout,
+		ReadTimeout:            cfg.ReadTimeout,
+		WriteTimeout:           cfg.WriteTimeout,
+		TLSConfig:              cfg.TLSConfig,
+		DisableNativePasswords: !cfg.AllowNativePasswords,
+		MultiStatements:        cfg.MultiStatements,
+		InterpolateParams:      cfg.InterpolateParams,
+		Params:                 params,
+	}, nil
+}
+
+// RegisterTLSConfig registers a named tls.Config with the MySQL driver,
+// loading a CA certificate and an optional client certificate/key from
+// disk. The name can then be used as DSNConfig.TLSConfig (and WithTLS).
+func RegisterTLSConfig(name, caFile, clientCertFile, clientKeyFile, serverName string) error {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("mysql: reading CA file: %w", err)
+	}
+
+	rootCertPool := x509.NewCertPool()
+	if ok := rootCertPool.AppendCertsFromPEM(pem); !ok {
+		return fmt.Errorf("mysql: failed to append CA certificate from %s", caFile)
+	}
+
+	tlsConfig := &tls.Config{RootCAs: rootCertPool, ServerName: serverName}
+
+	if clientCertFile != "" && clientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+		if err != nil {
+			return fmt.Errorf("mysql: loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return mysqldriver.RegisterTLSConfig(name, tlsConfig)
+}
@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDSNConfigFormatDSNRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  DSNConfig
+	}{
+		{
+			name: "minimal",
+			cfg:  DSNConfig{User: "root", Password: "secret", Host: "localhost", Port: "3306", Database: "app"},
+		},
+		{
+			name: "parseTime and collation",
+			cfg: DSNConfig{
+				User: "root", Password: "secret", Host: "db.internal", Port: "3306", Database: "app",
+				ParseTime: true, Collation: "utf8mb4_general_ci",
+			},
+		},
+		{
+			name: "custom charset",
+			cfg: DSNConfig{
+				User: "root", Password: "secret", Host: "localhost", Port: "3306", Database: "app",
+				Charset: "latin1",
+			},
+		},
+		{
+			name: "timeouts and interpolateParams",
+			cfg: DSNConfig{
+				User: "root", Password: "secret", Host: "localhost", Port: "3306", Database: "app",
+				Timeout: 5 * time.Second, ReadTimeout: 2 * time.Second, WriteTimeout: 2 * time.Second,
+				InterpolateParams: true,
+			},
+		},
+		{
+			name: "arbitrary extra params",
+			cfg: DSNConfig{
+				User: "root", Password: "secret", Host: "localhost", Port: "3306", Database: "app",
+				Params: map[string]string{"connectionAttributes": "foo:bar"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dsn := tc.cfg.FormatDSN()
+
+			got, err := ParseDSN(dsn)
+			if err != nil {
+				t.Fatalf("ParseDSN(%q): %v", dsn, err)
+			}
+
+			if got.User != tc.cfg.User {
+				t.Errorf("User = %q, want %q", got.User, tc.cfg.User)
+			}
+			if got.Password != tc.cfg.Password {
+				t.Errorf("Password = %q, want %q", got.Password, tc.cfg.Password)
+			}
+			if got.Host != tc.cfg.Host {
+				t.Errorf("Host = %q, want %q", got.Host, tc.cfg.Host)
+			}
+			if got.Port != tc.cfg.Port {
+				t.Errorf("Port = %q, want %q", got.Port, tc.cfg.Port)
+			}
+			if got.Database != tc.cfg.Database {
+				t.Errorf("Database = %q, want %q", got.Database, tc.cfg.Database)
+			}
+			if got.ParseTime != tc.cfg.ParseTime {
+				t.Errorf("ParseTime = %v, want %v", got.ParseTime, tc.cfg.ParseTime)
+			}
+			if got.InterpolateParams != tc.cfg.InterpolateParams {
+				t.Errorf("InterpolateParams = %v, want %v", got.InterpolateParams, tc.cfg.InterpolateParams)
+			}
+
+			wantCharset := tc.cfg.Charset
+			if wantCharset == "" {
+				wantCharset = defaultCharset
+			}
+			if got.Charset != wantCharset {
+				t.Errorf("Charset = %q, want %q", got.Charset, wantCharset)
+			}
+
+			for k, v := range tc.cfg.Params {
+				if got.Params[k] != v {
+					t.Errorf("Params[%q] = %q, want %q", k, got.Params[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestDSNConfigFormatDSNFixesMalformedAddress(t *testing.T) {
+	// The bug this replaced: fmt.Sprintf("%s:%s@tcp(%s:%s/%s)", ...) is
+	// missing the closing paren before the database name, producing
+	// "user:pass@tcp(host:port/db)" instead of
+	// "user:pass@tcp(host:port)/db". Assert the address is parenthesized
+	// correctly and the database name sits outside it.
+	dsn := DSNConfig{User: "root", Password: "secret", Host: "localhost", Port: "3306", Database: "app"}.FormatDSN()
+
+	if !strings.Contains(dsn, "@tcp(localhost:3306)/app") {
+		t.Fatalf("FormatDSN() = %q, want it to contain \"@tcp(localhost:3306)/app\"", dsn)
+	}
+}
+
+func TestParseDSNRejectsInvalidDSN(t *testing.T) {
+	if _, err := ParseDSN("not a dsn"); err == nil {
+		t.Fatal("ParseDSN(\"not a dsn\"): want error, got nil")
+	}
+}
@@ -0,0 +1,368 @@
+// Package migrate applies versioned .sql files, embedded via embed.FS,
+// against a MySQL database and records which versions have run in a
+// schema_migrations table.
+//
+// MySQL's DDL statements are not transactional -- CREATE TABLE and friends
+// implicitly commit whatever came before them -- so a Migrator cannot wrap
+// a migration in a transaction to make it atomic. Instead it holds a
+// GET_LOCK/RELEASE_LOCK advisory lock for the duration of Up/Down, which is
+// enough to stop two migrators (e.g. two replicas starting up at once from
+// a rolling deploy) from applying the same migration twice.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockName and lockTimeout configure the GET_LOCK call used to serialize
+// migrators. lockTimeout is in seconds, per GET_LOCK's own signature.
+const (
+	lockName    = "mysql_migrate"
+	lockTimeout = 10
+)
+
+// migration is one versioned pair of up/down scripts found in an embed.FS.
+// Down is optional; a migration with no matching "<version>_*.down.sql"
+// file can be applied but not reverted.
+type migration struct {
+	version     int64
+	description string
+	up          string
+	down        string
+	hasDown     bool
+}
+
+// Status reports whether a single migration version has been applied, and
+// whether its embedded script still matches the checksum recorded when it
+// was applied.
+type Status struct {
+	Version     int64
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+	Drifted     bool
+}
+
+// Migrator applies, reverts, and reports on the migrations found in an
+// embed.FS directory.
+type Migrator struct {
+	db         *sql.DB
+	migrations []migration
+}
+
+// New reads every "<version>_<description>.up.sql" (and optional matching
+// ".down.sql") file directly under dir in fsys, and returns a Migrator
+// ready to apply them against db. Migrations are ordered by their numeric
+// version prefix, e.g. "0001_create_users.up.sql".
+func New(db *sql.DB, fsys embed.FS, dir string) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: reading %s: %w", dir, err)
+	}
+
+	byVersion := make(map[int64]*migration)
+	var order []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		var isDown bool
+		base := strings.TrimSuffix(name, ".up.sql")
+		if base == name {
+			base = strings.TrimSuffix(name, ".down.sql")
+			if base == name {
+				continue // not a migration file
+			}
+			isDown = true
+		}
+
+		version, description, err := parseFilename(base)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := fsys.ReadFile(dir + "/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: reading %s: %w", name, err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &migration{version: version, description: description}
+			byVersion[version] = mig
+			order = append(order, version)
+		}
+		if isDown {
+			mig.down = string(data)
+			mig.hasDown = true
+		} else {
+			mig.up = string(data)
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	migrations := make([]migration, 0, len(order))
+	for _, version := range order {
+		mig := byVersion[version]
+		if mig.up == "" {
+			return nil, fmt.Errorf("migrate: version %d has no .up.sql file", version)
+		}
+		migrations = append(migrations, *mig)
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// checksum returns the hex-encoded SHA-256 of a migration script's
+// contents, recorded in schema_migrations so drift in an already-applied
+// script can be detected later.
+func checksum(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseFilename(base string) (version int64, description string, err error) {
+	parts := strings.SplitN(base, "_", 2)
+	version, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("migrate: %q does not start with a numeric version: %w", base, err)
+	}
+	if len(parts) == 2 {
+		description = parts[1]
+	}
+	return version, description, nil
+}
+
+// ensureVersionTable creates schema_migrations if it does not already exist.
+func (m *Migrator) ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT NOT NULL PRIMARY KEY,
+		applied_at DATETIME NOT NULL,
+		checksum   VARCHAR(64) NOT NULL
+	)`)
+	return err
+}
+
+// withLock acquires the GET_LOCK advisory lock on a dedicated connection,
+// runs fn, then releases it. All bookkeeping reads and writes happen on the
+// same connection so that a lock acquired in one session is visible to the
+// GET_LOCK/RELEASE_LOCK calls, per MySQL's per-connection lock semantics.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context, conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&got); err != nil {
+		return fmt.Errorf("migrate: acquiring lock %q: %w", lockName, err)
+	}
+	if got.Int64 != 1 {
+		return fmt.Errorf("migrate: could not acquire lock %q within %ds", lockName, lockTimeout)
+	}
+	defer conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+
+	if err := m.ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(ctx, conn)
+}
+
+// version returns the highest applied migration version, or 0 if none have
+// been applied. The caller must already hold the lock and have ensured the
+// version table exists.
+func version(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var v sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&v); err != nil {
+		return 0, err
+	}
+	return v.Int64, nil
+}
+
+// appliedChecksums returns the checksum recorded for every applied version.
+// The caller must already hold the lock and have ensured the version table
+// exists.
+func appliedChecksums(ctx context.Context, conn *sql.Conn) (map[int64]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]string)
+	for rows.Next() {
+		var v int64
+		var sum string
+		if err := rows.Scan(&v, &sum); err != nil {
+			return nil, err
+		}
+		applied[v] = sum
+	}
+	return applied, rows.Err()
+}
+
+// checkDrift compares the checksum recorded for each already-applied
+// migration against the checksum of its embedded .up.sql script, returning
+// an error naming the first version whose script has changed since it was
+// applied.
+func checkDrift(migrations []migration, applied map[int64]string) error {
+	for _, mig := range migrations {
+		stored, ok := applied[mig.version]
+		if !ok {
+			continue
+		}
+		if want := checksum(mig.up); stored != want {
+			return fmt.Errorf("migrate: checksum mismatch for already-applied version %d_%s: recorded %s, embedded script is now %s",
+				mig.version, mig.description, stored, want)
+		}
+	}
+	return nil
+}
+
+// Version returns the highest applied migration version, or 0 if none have
+// been applied.
+func (m *Migrator) Version() (int64, error) {
+	var v int64
+	err := m.withLock(context.Background(), func(ctx context.Context, conn *sql.Conn) error {
+		var err error
+		v, err = version(ctx, conn)
+		return err
+	})
+	return v, err
+}
+
+// Status reports, for every migration found in the embed.FS, whether it has
+// been applied and whether its script has drifted from what was recorded
+// when it was applied. Status never returns an error for drift -- it
+// surfaces it via Status.Drifted -- so callers can report on every
+// migration in one pass; Up refuses to run at all if any drift is found.
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(context.Background(), func(ctx context.Context, conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		applied := make(map[int64]time.Time)
+		for rows.Next() {
+			var v int64
+			var appliedAt time.Time
+			if err := rows.Scan(&v, &appliedAt); err != nil {
+				return err
+			}
+			applied[v] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		checksums, err := appliedChecksums(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]Status, len(m.migrations))
+		for i, mig := range m.migrations {
+			appliedAt, ok := applied[mig.version]
+			drifted := ok && checksums[mig.version] != checksum(mig.up)
+			statuses[i] = Status{Version: mig.version, Description: mig.description, Applied: ok, AppliedAt: appliedAt, Drifted: drifted}
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Up applies every migration with a version greater than the currently
+// applied version, in order. Each script runs on its own exec (not inside a
+// transaction -- MySQL's DDL implicitly commits, so a transaction buys
+// nothing) while the Migrator holds the advisory lock.
+//
+// Before applying anything, Up verifies that every already-applied
+// migration's checksum still matches its embedded script, and refuses to
+// run if it finds drift -- an applied script should never be silently
+// re-run with different contents.
+func (m *Migrator) Up() error {
+	return m.withLock(context.Background(), func(ctx context.Context, conn *sql.Conn) error {
+		current, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		applied, err := appliedChecksums(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDrift(m.migrations, applied); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if mig.version <= current {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, mig.up); err != nil {
+				return fmt.Errorf("migrate: applying %d_%s: %w", mig.version, mig.description, err)
+			}
+			_, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, applied_at, checksum) VALUES (?, ?, ?)",
+				mig.version, time.Now(), checksum(mig.up))
+			if err != nil {
+				return fmt.Errorf("migrate: recording %d_%s: %w", mig.version, mig.description, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the single most recently applied migration by running its
+// ".down.sql" script and removing its schema_migrations row. It returns an
+// error if the most recently applied migration has no down script.
+func (m *Migrator) Down() error {
+	return m.withLock(context.Background(), func(ctx context.Context, conn *sql.Conn) error {
+		current, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			return fmt.Errorf("migrate: no applied migrations to revert")
+		}
+
+		var mig *migration
+		for i := range m.migrations {
+			if m.migrations[i].version == current {
+				mig = &m.migrations[i]
+				break
+			}
+		}
+		if mig == nil {
+			return fmt.Errorf("migrate: applied version %d has no matching migration in %T", current, m)
+		}
+		if !mig.hasDown {
+			return fmt.Errorf("migrate: version %d_%s has no .down.sql file", mig.version, mig.description)
+		}
+
+		if _, err := conn.ExecContext(ctx, mig.down); err != nil {
+			return fmt.Errorf("migrate: reverting %d_%s: %w", mig.version, mig.description, err)
+		}
+		_, err = conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.version)
+		if err != nil {
+			return fmt.Errorf("migrate: un-recording %d_%s: %w", mig.version, mig.description, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package migrate
+
+import (
+	"embed"
+	"testing"
+)
+
+//go:embed testdata/*.sql
+var testdataFS embed.FS
+
+//go:embed testdata_missing_up/*.sql
+var missingUpFS embed.FS
+
+func TestParseFilename(t *testing.T) {
+	cases := []struct {
+		name        string
+		base        string
+		wantVersion int64
+		wantDesc    string
+		wantErr     bool
+	}{
+		{name: "version and description", base: "0001_create_users", wantVersion: 1, wantDesc: "create_users"},
+		{name: "version only", base: "42", wantVersion: 42, wantDesc: ""},
+		{name: "leading zeros", base: "0002_add_email", wantVersion: 2, wantDesc: "add_email"},
+		{name: "non-numeric version", base: "abc_create_users", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, description, err := parseFilename(tc.base)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseFilename(%q) = nil error, want one", tc.base)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFilename(%q): %v", tc.base, err)
+			}
+			if version != tc.wantVersion || description != tc.wantDesc {
+				t.Fatalf("parseFilename(%q) = (%d, %q), want (%d, %q)",
+					tc.base, version, description, tc.wantVersion, tc.wantDesc)
+			}
+		})
+	}
+}
+
+func TestNewOrdersByVersionAndTracksDown(t *testing.T) {
+	m, err := New(nil, testdataFS, "testdata")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if len(m.migrations) != 2 {
+		t.Fatalf("len(migrations) = %d, want 2", len(m.migrations))
+	}
+	if got, want := m.migrations[0].version, int64(1); got != want {
+		t.Fatalf("migrations[0].version = %d, want %d", got, want)
+	}
+	if got, want := m.migrations[1].version, int64(2); got != want {
+		t.Fatalf("migrations[1].version = %d, want %d", got, want)
+	}
+	if !m.migrations[0].hasDown {
+		t.Fatal("migrations[0] (version 1) should have a .down.sql file")
+	}
+	if m.migrations[1].hasDown {
+		t.Fatal("migrations[1] (version 2) should not have a .down.sql file")
+	}
+}
+
+func TestNewRejectsVersionWithNoUpFile(t *testing.T) {
+	if _, err := New(nil, missingUpFS, "testdata_missing_up"); err == nil {
+		t.Fatal("New: want error for a version with only a .down.sql file, got nil")
+	}
+}
+
+func TestChecksumDiffersOnContentChange(t *testing.T) {
+	a := checksum("CREATE TABLE t (id INT);")
+	b := checksum("CREATE TABLE t (id INT NOT NULL);")
+	if a == b {
+		t.Fatal("checksum: different scripts produced the same checksum")
+	}
+	if got := checksum("CREATE TABLE t (id INT);"); got != a {
+		t.Fatal("checksum: same script produced different checksums")
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	migrations := []migration{
+		{version: 1, description: "create_users", up: "CREATE TABLE users (id INT);"},
+		{version: 2, description: "add_email", up: "ALTER TABLE users ADD COLUMN email VARCHAR(255);"},
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		applied := map[int64]string{1: checksum(migrations[0].up)}
+		if err := checkDrift(migrations, applied); err != nil {
+			t.Fatalf("checkDrift: %v", err)
+		}
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		applied := map[int64]string{1: "not-the-real-checksum"}
+		if err := checkDrift(migrations, applied); err == nil {
+			t.Fatal("checkDrift: want error for a changed already-applied script, got nil")
+		}
+	})
+}
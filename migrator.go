@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"database/sql"
+	"embed"
+
+	"github.com/skeptycal/mysql/migrate"
+)
+
+// Migrator opens dbname and returns a migrate.Migrator that applies the
+// "<version>_<description>.up.sql" (and optional ".down.sql") files found
+// directly under dir in fsys against it.
+//
+// The connection is opened with MultiStatements enabled, unlike db.Open's
+// regular DSN: migration scripts routinely contain more than one
+// semicolon-separated DDL statement, and go-sql-driver/mysql rejects those
+// on a connection that doesn't opt in.
+func (db mySQL) Migrator(dbname string, fsys embed.FS, dir string) (*migrate.Migrator, error) {
+	dsn := DSNConfig{
+		User:            db.username,
+		Password:        db.password,
+		Host:            db.host,
+		Port:            db.port,
+		Database:        dbname,
+		ParseTime:       true,
+		TLSConfig:       db.tls,
+		Params:          db.params,
+		MultiStatements: true,
+	}.FormatDSN()
+
+	conn, err := sql.Open(mySqlDriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.tunePool(conn)
+
+	return migrate.New(conn, fsys, dir)
+}
@@ -0,0 +1,24 @@
+// Package sqlxext adds github.com/jmoiron/sqlx support on top of the base
+// mysql package's MySQL interface. It is kept separate from that package so
+// that callers who only want the plain *sql.DB path never pull the sqlx
+// dependency into their build.
+package sqlxext
+
+import (
+	"github.com/jmoiron/sqlx"
+
+	skmysql "github.com/skeptycal/mysql"
+)
+
+// OpenX opens dbname through db and wraps the result in a *sqlx.DB, giving
+// callers named parameters, StructScan, and Get/Select without wrapping
+// (*sql.DB) themselves. Since it wraps the *sql.DB that db.Open already
+// produced, the pool tuning applied there (ConnMaxLifetime, MaxOpenConns,
+// MaxIdleConns) carries over unchanged.
+func OpenX(db skmysql.MySQL, dbname string) (*sqlx.DB, error) {
+	conn, err := db.Open(dbname)
+	if err != nil {
+		return nil, err
+	}
+	return sqlx.NewDb(conn, "mysql"), nil
+}
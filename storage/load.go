@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/skeptycal/mysql/storage/mysql"
+	"github.com/skeptycal/mysql/storage/postgres"
+	"github.com/skeptycal/mysql/storage/sqlite3"
+
+	"gopkg.in/yaml.v2"
+)
+
+// envType names the environment variable that selects the backend for
+// LoadEnv, e.g. "mysql", "postgres", or "sqlite3".
+const envType = "STORAGE_TYPE"
+
+// LoadEnv builds a Config from environment variables, choosing the backend
+// via STORAGE_TYPE and reading that backend's fields from backend-prefixed
+// variables:
+//
+//	mysql:    MYSQL_HOST, MYSQL_PORT, MYSQL_DATABASE, MYSQL_USER, MYSQL_PASSWORD
+//	postgres: POSTGRES_HOST, POSTGRES_PORT, POSTGRES_DATABASE, POSTGRES_USER, POSTGRES_PASSWORD
+//	sqlite3:  SQLITE3_FILE
+//
+// It is the storage.Config sibling of the root package's NewMySQL, which
+// reads MYSQL_USERNAME/MYSQL_PASSWORD directly into a mysql.MySQL instead
+// of a storage.Config; the username variable differs (MYSQL_USER here,
+// MYSQL_USERNAME there) because NewMySQL predates this package and its names
+// are part of its public contract.
+func LoadEnv() (Config, error) {
+	switch typ := BackendType(os.Getenv(envType)); typ {
+	case TypeMySQL:
+		return Config{
+			Type: TypeMySQL,
+			MySQL: mysql.Config{
+				Host:     os.Getenv("MYSQL_HOST"),
+				Port:     envInt("MYSQL_PORT"),
+				Database: os.Getenv("MYSQL_DATABASE"),
+				User:     os.Getenv("MYSQL_USER"),
+				Password: os.Getenv("MYSQL_PASSWORD"),
+			},
+		}, nil
+	case TypePostgres:
+		return Config{
+			Type: TypePostgres,
+			Postgres: postgres.Config{
+				Host:     os.Getenv("POSTGRES_HOST"),
+				Port:     envInt("POSTGRES_PORT"),
+				Database: os.Getenv("POSTGRES_DATABASE"),
+				User:     os.Getenv("POSTGRES_USER"),
+				Password: os.Getenv("POSTGRES_PASSWORD"),
+			},
+		}, nil
+	case TypeSQLite3:
+		return Config{
+			Type:    TypeSQLite3,
+			SQLite3: sqlite3.Config{File: os.Getenv("SQLITE3_FILE")},
+		}, nil
+	case "":
+		return Config{}, fmt.Errorf("storage: %s is not set", envType)
+	default:
+		return Config{}, fmt.Errorf("storage: unknown backend type %q in %s", typ, envType)
+	}
+}
+
+// envInt parses name as an int, returning 0 if it is unset or invalid. The
+// mysql and postgres backends do not substitute a default for a zero Port:
+// it goes into the DSN as port 0.
+func envInt(name string) int {
+	n, _ := strconv.Atoi(os.Getenv(name))
+	return n
+}
+
+// LoadFile reads a Config from a JSON or YAML file, chosen by the file's
+// extension (.json, or .yml/.yaml). It is one of several config sources;
+// see LoadEnv for the environment-variable source.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		return Config{}, fmt.Errorf("storage: unrecognized config extension %q", ext)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("storage: parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveFile writes cfg to path as JSON or YAML, chosen by the file's
+// extension (.json, or .yml/.yaml).
+func SaveFile(path string, cfg Config) error {
+	var data []byte
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		data, err = json.MarshalIndent(cfg, "", "  ")
+	case ".yml", ".yaml":
+		data, err = yaml.Marshal(cfg)
+	default:
+		return fmt.Errorf("storage: unrecognized config extension %q", ext)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
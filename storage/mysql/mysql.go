@@ -0,0 +1,172 @@
+// Package mysql is the MySQL storage.Storage backend: it satisfies that
+// interface structurally so it never needs to import the storage package.
+package mysql
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"time"
+
+	skmysql "github.com/skeptycal/mysql"
+	"github.com/skeptycal/mysql/migrate"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// SSL holds the TLS settings for a MySQL connection.
+type SSL struct {
+	Mode       string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	CAFile     string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	ClientCert string `json:"clientCert,omitempty" yaml:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+}
+
+// Config holds the fields needed to connect to a MySQL, MariaDB, Percona
+// Server, or Google CloudSQL instance.
+type Config struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Database string `json:"database" yaml:"database"`
+	User     string `json:"user" yaml:"user"`
+	Password string `json:"password" yaml:"password"`
+	SSL      SSL    `json:"ssl,omitempty" yaml:"ssl,omitempty"`
+
+	ConnMaxLifetime time.Duration `json:"connMaxLifetime,omitempty" yaml:"connMaxLifetime,omitempty"`
+	MaxOpenConns    int           `json:"maxOpenConns,omitempty" yaml:"maxOpenConns,omitempty"`
+	MaxIdleConns    int           `json:"maxIdleConns,omitempty" yaml:"maxIdleConns,omitempty"`
+
+	// MigrationsFS and MigrationsDir, if set, are passed to migrate.New by
+	// Migrate. Leave MigrationsDir empty to use this backend without a
+	// migrator.
+	MigrationsFS  embed.FS `json:"-" yaml:"-"`
+	MigrationsDir string   `json:"migrationsDir,omitempty" yaml:"migrationsDir,omitempty"`
+}
+
+// DB is the MySQL storage backend produced by Config.Open.
+type DB struct {
+	cfg    Config
+	tlsCfg string
+	conn   *sql.DB
+}
+
+// Open validates cfg, opens a connection pool, and returns the backend. The
+// database name used for the initial connection is cfg.Database; use
+// Open(dbname) on the returned DB to reconnect to a different database.
+func (c Config) Open() (*DB, error) {
+	if c.Host == "" {
+		return nil, errors.New("mysql: Config.Host is required")
+	}
+	if c.User == "" {
+		return nil, errors.New("mysql: Config.User is required")
+	}
+
+	db := &DB{cfg: c}
+	if c.SSL.CAFile != "" {
+		db.tlsCfg = fmt.Sprintf("storage-mysql:%s/%s", c.Host, c.Database)
+		if err := skmysql.RegisterTLSConfig(db.tlsCfg, c.SSL.CAFile, c.SSL.ClientCert, c.SSL.ClientKey, c.SSL.ServerName); err != nil {
+			return nil, err
+		}
+	} else if c.SSL.Mode != "" {
+		db.tlsCfg = c.SSL.Mode
+	}
+
+	conn, err := db.open(c.Database)
+	if err != nil {
+		return nil, err
+	}
+	db.conn = conn
+	return db, nil
+}
+
+func (db *DB) open(dbname string) (*sql.DB, error) {
+	conn, err := sql.Open("mysql", db.DSN(dbname))
+	if err != nil {
+		return nil, err
+	}
+	if db.cfg.ConnMaxLifetime > 0 {
+		conn.SetConnMaxLifetime(db.cfg.ConnMaxLifetime)
+	}
+	if db.cfg.MaxOpenConns > 0 {
+		conn.SetMaxOpenConns(db.cfg.MaxOpenConns)
+	}
+	if db.cfg.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(db.cfg.MaxIdleConns)
+	}
+	return conn, nil
+}
+
+// Open reconnects to dbname, closing any existing connection first.
+func (db *DB) Open(dbname string) (*sql.DB, error) {
+	conn, err := db.open(dbname)
+	if err != nil {
+		return nil, err
+	}
+	if db.conn != nil {
+		db.conn.Close()
+	}
+	db.conn = conn
+	return conn, nil
+}
+
+// DSN returns the DSN used to connect to dbname.
+func (db *DB) DSN(dbname string) string {
+	return skmysql.DSNConfig{
+		User:      db.cfg.User,
+		Password:  db.cfg.Password,
+		Host:      db.cfg.Host,
+		Port:      fmt.Sprintf("%d", db.cfg.Port),
+		Database:  dbname,
+		ParseTime: true,
+		TLSConfig: db.tlsCfg,
+	}.FormatDSN()
+}
+
+// Migrate applies any pending schema migrations found under
+// cfg.MigrationsDir in cfg.MigrationsFS, via the migrate package. It
+// returns an error if cfg.MigrationsDir was left unset.
+//
+// Migrate opens its own connection with MultiStatements enabled rather than
+// reusing db.conn, since migrate scripts routinely contain more than one
+// semicolon-separated DDL statement and go-sql-driver/mysql rejects those
+// on a connection that doesn't opt in.
+func (db *DB) Migrate() error {
+	if db.cfg.MigrationsDir == "" {
+		return errors.New("mysql: Migrate requires Config.MigrationsDir (and MigrationsFS) to be set")
+	}
+
+	dsn := skmysql.DSNConfig{
+		User:            db.cfg.User,
+		Password:        db.cfg.Password,
+		Host:            db.cfg.Host,
+		Port:            fmt.Sprintf("%d", db.cfg.Port),
+		Database:        db.cfg.Database,
+		ParseTime:       true,
+		TLSConfig:       db.tlsCfg,
+		MultiStatements: true,
+	}.FormatDSN()
+
+	conn, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	m, err := migrate.New(conn, db.cfg.MigrationsFS, db.cfg.MigrationsDir)
+	if err != nil {
+		return err
+	}
+	return m.Up()
+}
+
+// Ping verifies that the connection to the database is still alive.
+func (db *DB) Ping() error {
+	return db.conn.Ping()
+}
+
+// Close closes the connection pool.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
@@ -0,0 +1,123 @@
+// Package postgres is the PostgreSQL storage.Storage backend: it satisfies
+// that interface structurally so it never needs to import the storage
+// package.
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// SSL holds the TLS settings for a Postgres connection. Only Mode is used,
+// as the DSN's sslmode; CAFile, ClientCert, ClientKey and ServerName are not
+// read by this backend.
+type SSL struct {
+	Mode       string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	CAFile     string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	ClientCert string `json:"clientCert,omitempty" yaml:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+}
+
+// Config holds the fields needed to connect to a PostgreSQL instance.
+type Config struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	Database string `json:"database" yaml:"database"`
+	User     string `json:"user" yaml:"user"`
+	Password string `json:"password" yaml:"password"`
+	SSL      SSL    `json:"ssl,omitempty" yaml:"ssl,omitempty"`
+
+	ConnMaxLifetime time.Duration `json:"connMaxLifetime,omitempty" yaml:"connMaxLifetime,omitempty"`
+	MaxOpenConns    int           `json:"maxOpenConns,omitempty" yaml:"maxOpenConns,omitempty"`
+	MaxIdleConns    int           `json:"maxIdleConns,omitempty" yaml:"maxIdleConns,omitempty"`
+}
+
+// DB is the Postgres storage backend produced by Config.Open.
+type DB struct {
+	cfg  Config
+	conn *sql.DB
+}
+
+// Open validates cfg, opens a connection pool, and returns the backend. The
+// database name used for the initial connection is cfg.Database; use
+// Open(dbname) on the returned DB to reconnect to a different database.
+func (c Config) Open() (*DB, error) {
+	if c.Host == "" {
+		return nil, errors.New("postgres: Config.Host is required")
+	}
+	if c.User == "" {
+		return nil, errors.New("postgres: Config.User is required")
+	}
+
+	db := &DB{cfg: c}
+	conn, err := db.open(c.Database)
+	if err != nil {
+		return nil, err
+	}
+	db.conn = conn
+	return db, nil
+}
+
+func (db *DB) open(dbname string) (*sql.DB, error) {
+	conn, err := sql.Open("postgres", db.DSN(dbname))
+	if err != nil {
+		return nil, err
+	}
+	if db.cfg.ConnMaxLifetime > 0 {
+		conn.SetConnMaxLifetime(db.cfg.ConnMaxLifetime)
+	}
+	if db.cfg.MaxOpenConns > 0 {
+		conn.SetMaxOpenConns(db.cfg.MaxOpenConns)
+	}
+	if db.cfg.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(db.cfg.MaxIdleConns)
+	}
+	return conn, nil
+}
+
+// Open reconnects to dbname, closing any existing connection first.
+func (db *DB) Open(dbname string) (*sql.DB, error) {
+	conn, err := db.open(dbname)
+	if err != nil {
+		return nil, err
+	}
+	if db.conn != nil {
+		db.conn.Close()
+	}
+	db.conn = conn
+	return conn, nil
+}
+
+// DSN returns the DSN used to connect to dbname.
+func (db *DB) DSN(dbname string) string {
+	sslmode := "disable"
+	if db.cfg.SSL.Mode != "" {
+		sslmode = db.cfg.SSL.Mode
+	}
+	return fmt.Sprintf("host=%s port=%d dbname=%s user=%s password=%s sslmode=%s",
+		db.cfg.Host, db.cfg.Port, dbname, db.cfg.User, db.cfg.Password, sslmode)
+}
+
+// Migrate applies any pending schema migrations.
+//
+// Not Implemented: the migrate package's locking (GET_LOCK/RELEASE_LOCK)
+// and DDL-transaction assumptions are MySQL-specific, so there is no
+// migrator for this backend yet. See storage/mysql.DB.Migrate.
+func (db *DB) Migrate() error {
+	return errors.New("postgres: Migrate not implemented")
+}
+
+// Ping verifies that the connection to the database is still alive.
+func (db *DB) Ping() error {
+	return db.conn.Ping()
+}
+
+// Close closes the connection pool.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
@@ -0,0 +1,95 @@
+// Package sqlite3 is the SQLite storage.Storage backend: it satisfies that
+// interface structurally so it never needs to import the storage package.
+package sqlite3
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Config holds the fields needed to open a SQLite database file. Unlike the
+// network backends, SQLite has no host, port, or credentials: File is the
+// path to the database file on disk.
+type Config struct {
+	File string `json:"file" yaml:"file"`
+
+	MaxOpenConns int `json:"maxOpenConns,omitempty" yaml:"maxOpenConns,omitempty"`
+	MaxIdleConns int `json:"maxIdleConns,omitempty" yaml:"maxIdleConns,omitempty"`
+}
+
+// DB is the SQLite storage backend produced by Config.Open.
+type DB struct {
+	cfg  Config
+	conn *sql.DB
+}
+
+// Open validates cfg, opens the database file, and returns the backend.
+func (c Config) Open() (*DB, error) {
+	if c.File == "" {
+		return nil, errors.New("sqlite3: Config.File is required")
+	}
+
+	db := &DB{cfg: c}
+	conn, err := db.open(c.File)
+	if err != nil {
+		return nil, err
+	}
+	db.conn = conn
+	return db, nil
+}
+
+func (db *DB) open(dbname string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", db.DSN(dbname))
+	if err != nil {
+		return nil, err
+	}
+	if db.cfg.MaxOpenConns > 0 {
+		conn.SetMaxOpenConns(db.cfg.MaxOpenConns)
+	}
+	if db.cfg.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(db.cfg.MaxIdleConns)
+	}
+	return conn, nil
+}
+
+// Open reconnects to the database file named by dbname, closing any
+// existing connection first. dbname overrides cfg.File for this call.
+func (db *DB) Open(dbname string) (*sql.DB, error) {
+	conn, err := db.open(dbname)
+	if err != nil {
+		return nil, err
+	}
+	if db.conn != nil {
+		db.conn.Close()
+	}
+	db.conn = conn
+	return conn, nil
+}
+
+// DSN returns the DSN used to open dbname. dbname is the path to the
+// database file; SQLite has no separate server-side database namespace.
+func (db *DB) DSN(dbname string) string {
+	return fmt.Sprintf("file:%s", dbname)
+}
+
+// Migrate applies any pending schema migrations.
+//
+// Not Implemented: the migrate package's locking (GET_LOCK/RELEASE_LOCK)
+// and DDL-transaction assumptions are MySQL-specific, so there is no
+// migrator for this backend yet. See storage/mysql.DB.Migrate.
+func (db *DB) Migrate() error {
+	return errors.New("sqlite3: Migrate not implemented")
+}
+
+// Ping verifies that the connection to the database is still alive.
+func (db *DB) Ping() error {
+	return db.conn.Ping()
+}
+
+// Close closes the connection pool.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
@@ -0,0 +1,60 @@
+// Package storage defines a backend-agnostic Storage interface and a Config
+// that selects and configures one of the concrete backends in
+// storage/mysql, storage/postgres, or storage/sqlite3.
+//
+// It generalizes the MySQL-only interface in the root mysql package:
+// callers that previously depended on mysql.MySQL can switch
+// databases by changing a Config value instead of their code.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/skeptycal/mysql/storage/mysql"
+	"github.com/skeptycal/mysql/storage/postgres"
+	"github.com/skeptycal/mysql/storage/sqlite3"
+)
+
+// Storage is the interface implemented by every supported database backend.
+type Storage interface {
+	Open(dbname string) (*sql.DB, error)
+	DSN(dbname string) string
+	Migrate() error
+	Ping() error
+	Close() error
+}
+
+// BackendType names a supported storage backend.
+type BackendType string
+
+const (
+	TypeMySQL    BackendType = "mysql"
+	TypePostgres BackendType = "postgres"
+	TypeSQLite3  BackendType = "sqlite3"
+)
+
+// Config selects and configures a storage backend. Only the field named by
+// Type is read; the others are ignored. This mirrors dex's backend config
+// layout, where each storage driver owns its own connection fields.
+type Config struct {
+	Type BackendType `json:"type" yaml:"type"`
+
+	MySQL    mysql.Config    `json:"mysql,omitempty" yaml:"mysql,omitempty"`
+	Postgres postgres.Config `json:"postgres,omitempty" yaml:"postgres,omitempty"`
+	SQLite3  sqlite3.Config  `json:"sqlite3,omitempty" yaml:"sqlite3,omitempty"`
+}
+
+// Open builds the backend named by c.Type.
+func (c Config) Open() (Storage, error) {
+	switch c.Type {
+	case TypeMySQL:
+		return c.MySQL.Open()
+	case TypePostgres:
+		return c.Postgres.Open()
+	case TypeSQLite3:
+		return c.SQLite3.Open()
+	default:
+		return nil, fmt.Errorf("storage: unknown backend type %q", c.Type)
+	}
+}